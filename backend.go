@@ -0,0 +1,180 @@
+package gkeyring
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/godbus/dbus"
+)
+
+// BackendKind identifies which Secret Service implementation a Service is
+// talking to. Implementations differ in which optional properties they
+// expose, so callers can use this to adapt their own behavior.
+type BackendKind int
+
+const (
+	// BackendUnknown means the backend could not be identified.
+	BackendUnknown BackendKind = iota
+	// BackendGnomeKeyring is gnome-keyring-daemon.
+	BackendGnomeKeyring
+	// BackendKeePassXC is KeePassXC's built-in Secret Service server.
+	BackendKeePassXC
+	// BackendKWallet is KWallet's ksecretservice bridge.
+	BackendKWallet
+)
+
+func (b BackendKind) String() string {
+	switch b {
+	case BackendGnomeKeyring:
+		return "GnomeKeyring"
+	case BackendKeePassXC:
+		return "KeePassXC"
+	case BackendKWallet:
+		return "KWallet"
+	default:
+		return "Unknown"
+	}
+}
+
+// introspectNode holds the handful of fields of the standard D-Bus
+// introspection XML schema that detectBackend needs: the interfaces a node
+// implements.
+type introspectNode struct {
+	Interfaces []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"interface"`
+}
+
+// Vendor interfaces that a backend's Secret Service implementation exposes
+// on servicePath in addition to the standard org.freedesktop.Secret.*
+// interfaces, used by detectBackendByIntrospection to tell backends apart.
+const (
+	kwalletBridgeInterface = "org.kde.KWallet.FreedesktopSecretBridge"
+	keepassxcInterface     = "org.keepassxc.KeePassXC.Secrets"
+)
+
+// detectBackend identifies the Secret Service implementation behind conn.
+// Detection failures are non-fatal; callers fall back to BackendUnknown.
+func detectBackend(conn *dbus.Conn) BackendKind {
+	if b := detectBackendByIntrospection(conn); b != BackendUnknown {
+		return b
+	}
+
+	return detectBackendByProcess(conn)
+}
+
+// detectBackendByIntrospection identifies the backend by introspecting
+// servicePath and looking for a vendor interface specific to one of the
+// known backends. Unlike detectBackendByProcess, this works for any peer,
+// local or remote, since introspection is just another D-Bus call - but it
+// can't tell apart backends that don't advertise a vendor interface of
+// their own, gnome-keyring among them.
+func detectBackendByIntrospection(conn *dbus.Conn) BackendKind {
+	var xmlDoc string
+	err := conn.Object(serviceName, servicePath).
+		Call("org.freedesktop.DBus.Introspectable.Introspect", 0).Store(&xmlDoc)
+	if err != nil {
+		return BackendUnknown
+	}
+
+	var node introspectNode
+	if err := xml.Unmarshal([]byte(xmlDoc), &node); err != nil {
+		return BackendUnknown
+	}
+
+	for _, iface := range node.Interfaces {
+		switch iface.Name {
+		case kwalletBridgeInterface:
+			return BackendKWallet
+		case keepassxcInterface:
+			return BackendKeePassXC
+		}
+	}
+
+	return BackendUnknown
+}
+
+// detectBackendByProcess is a fallback for backends that introspection
+// can't identify: it resolves serviceName's unique bus name to a PID and
+// reads /proc/<pid>/comm. This only works for peers on the same host and
+// the same Linux procfs as us, so it silently yields BackendUnknown for a
+// remote bus or a sandboxed/non-Linux peer, same as a failed introspection
+// does.
+func detectBackendByProcess(conn *dbus.Conn) BackendKind {
+	var owner string
+	if err := conn.BusObject().Call("org.freedesktop.DBus.GetNameOwner", 0, serviceName).Store(&owner); err != nil {
+		return BackendUnknown
+	}
+
+	var pid uint32
+	if err := conn.BusObject().Call("org.freedesktop.DBus.GetConnectionUnixProcessID", 0, owner).Store(&pid); err != nil {
+		return BackendUnknown
+	}
+
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return BackendUnknown
+	}
+
+	switch name := strings.TrimSpace(string(comm)); {
+	case strings.Contains(name, "keepassxc"):
+		return BackendKeePassXC
+	case strings.Contains(name, "kwalletd"):
+		return BackendKWallet
+	case strings.Contains(name, "gnome-keyring"):
+		return BackendGnomeKeyring
+	default:
+		return BackendUnknown
+	}
+}
+
+// compatDefaults holds the value gkeyring substitutes for a given
+// "interface.property" when the backend doesn't expose it at all, such as
+// KeePassXC omitting org.freedesktop.Secret.Item.Type.
+var compatDefaults = map[string]dbus.Variant{
+	itemInterface + ".Type": dbus.MakeVariant("org.freedesktop.Secret.Generic"),
+}
+
+// isMissingPropertyError reports whether err is the kind of dbus error a
+// backend raises for a property it simply doesn't implement, as opposed to
+// a real failure. getProperty wraps the raw dbus.Error in a *DbusError and
+// then in a fmt.Errorf, so this unwraps with errors.As rather than asserting
+// on err's own type.
+func isMissingPropertyError(err error) bool {
+	var dbusErr *DbusError
+	if !errors.As(err, &dbusErr) {
+		return false
+	}
+
+	switch dbusErr.Name {
+	case "org.freedesktop.DBus.Error.UnknownProperty",
+		"org.freedesktop.DBus.Error.InvalidArgs":
+		return true
+	}
+
+	return false
+}
+
+// getPropertyCompat is like getProperty, but substitutes a known default
+// when the backend doesn't implement the requested property instead of
+// failing outright. It consults s.Backend in addition to the dbus error
+// name, so a backend we've already identified as needing a given default
+// (KeePassXC for Item.Type) gets it even if that backend raises an error
+// name isMissingPropertyError doesn't recognize.
+func (s *Service) getPropertyCompat(path dbus.ObjectPath, iface, name string) (dbus.Variant, error) {
+	v, err := s.getProperty(path, iface, name)
+	if err == nil {
+		return v, nil
+	}
+
+	if isMissingPropertyError(err) || s.Backend == BackendKeePassXC {
+		if def, ok := compatDefaults[iface+"."+name]; ok {
+			return def, nil
+		}
+	}
+
+	return v, err
+}
@@ -0,0 +1,20 @@
+package gkeyring
+
+import (
+	"fmt"
+)
+
+// Example_isMissingPropertyError documents that isMissingPropertyError must
+// unwrap the error chain: getProperty wraps the *DbusError it builds in a
+// further fmt.Errorf, so a bare type assertion on err never matches.
+func Example_isMissingPropertyError() {
+	missing := &DbusError{Name: "org.freedesktop.DBus.Error.UnknownProperty"}
+	wrapped := fmt.Errorf("get property %s.%s on %s: %w", itemInterface, "Type", "/", missing)
+
+	fmt.Println(isMissingPropertyError(wrapped))
+	fmt.Println(isMissingPropertyError(fmt.Errorf("some other failure")))
+
+	// Output:
+	// true
+	// false
+}
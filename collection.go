@@ -0,0 +1,147 @@
+package gkeyring
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus"
+)
+
+// Collection is a single keyring collection, such as "login".
+type Collection struct {
+	service *Service
+	path    dbus.ObjectPath
+}
+
+// Path returns the dbus object path backing this collection.
+func (c *Collection) Path() dbus.ObjectPath {
+	return c.path
+}
+
+// Label returns the collection's human readable label.
+func (c *Collection) Label() (string, error) {
+	v, err := c.service.getProperty(c.path, collectionInterface, "Label")
+	if err != nil {
+		return "", err
+	}
+
+	s, ok := v.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected type for Label property: %T", v.Value())
+	}
+
+	return s, nil
+}
+
+// Locked reports whether the collection is currently locked.
+func (c *Collection) Locked() (bool, error) {
+	v, err := c.service.getProperty(c.path, collectionInterface, "Locked")
+	if err != nil {
+		return false, err
+	}
+
+	locked, ok := v.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected type for Locked property: %T", v.Value())
+	}
+
+	return locked, nil
+}
+
+// Items returns every item stored in the collection.
+func (c *Collection) Items() ([]*Item, error) {
+	v, err := c.service.getProperty(c.path, collectionInterface, "Items")
+	if err != nil {
+		return nil, err
+	}
+
+	paths, ok := v.Value().([]dbus.ObjectPath)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type for Items property: %T", v.Value())
+	}
+
+	items := make([]*Item, len(paths))
+	for i, path := range paths {
+		items[i] = &Item{service: c.service, path: path}
+	}
+
+	return items, nil
+}
+
+// SearchItems returns the items in this collection matching attrs.
+func (c *Collection) SearchItems(attrs map[string]string) ([]*Item, error) {
+	object := c.service.conn.Object(serviceName, c.path)
+
+	var paths []dbus.ObjectPath
+	err := object.Call(collectionInterface+".SearchItems", 0, attrs).Store(&paths)
+	if err != nil {
+		return nil, fmt.Errorf("search items in %s: %w", c.path, wrapDbusError(err))
+	}
+
+	items := make([]*Item, len(paths))
+	for i, path := range paths {
+		items[i] = &Item{service: c.service, path: path}
+	}
+
+	return items, nil
+}
+
+// CreateItem creates a new item in the collection with the given label and
+// attributes, storing value via session.
+func (c *Collection) CreateItem(label string, attributes map[string]string, session *Session, value string) (*Item, error) {
+	return c.CreateItemContext(context.Background(), label, attributes, session, value)
+}
+
+// CreateItemContext is like CreateItem, but aborts a pending unlock prompt
+// once ctx is done.
+func (c *Collection) CreateItemContext(ctx context.Context, label string, attributes map[string]string, session *Session, value string) (*Item, error) {
+	properties := map[string]dbus.Variant{
+		itemInterface + ".Label":      dbus.MakeVariant(label),
+		itemInterface + ".Attributes": dbus.MakeVariant(attributes),
+	}
+
+	sec, err := session.encodeSecret(value)
+	if err != nil {
+		return nil, fmt.Errorf("create item %q in %s: %w", label, c.path, err)
+	}
+
+	object := c.service.conn.Object(serviceName, c.path)
+
+	var item, prompt dbus.ObjectPath
+	err = object.Call(collectionInterface+".CreateItem", 0,
+		properties, sec, true).Store(&item, &prompt)
+	if err != nil {
+		return nil, fmt.Errorf("create item %q in %s: %w", label, c.path, wrapDbusError(err))
+	}
+
+	_, _, err = c.service.handlePromptContext(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("create item %q in %s: %w", label, c.path, err)
+	}
+
+	return &Item{service: c.service, path: item}, nil
+}
+
+// Delete removes the collection and everything in it.
+func (c *Collection) Delete() error {
+	return c.DeleteContext(context.Background())
+}
+
+// DeleteContext is like Delete, but aborts a pending unlock prompt once ctx
+// is done.
+func (c *Collection) DeleteContext(ctx context.Context) error {
+	object := c.service.conn.Object(serviceName, c.path)
+
+	var prompt dbus.ObjectPath
+	err := object.Call(collectionInterface+".Delete", 0).Store(&prompt)
+	if err != nil {
+		return fmt.Errorf("delete collection %s: %w", c.path, wrapDbusError(err))
+	}
+
+	_, _, err = c.service.handlePromptContext(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("delete collection %s: %w", c.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package gkeyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// dhPrime is the 1024-bit MODP group prime from RFC 2409, section 6.1
+// (generator 2), which the Secret Service spec mandates for the
+// dh-ietf1024-sha256-aes128-cbc-pkcs7 transport algorithm.
+var dhPrime, _ = new(big.Int).SetString(""+
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD"+
+	"129024E088A67CC74020BBEA63B139B22514A08798E3404"+
+	"DDEF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C"+
+	"245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406"+
+	"B7EDEE386BFB5A899FA5AE9F24117C4B1FE649286651ECE"+
+	"65381FFFFFFFFFFFFFFFF", 16)
+
+const dhGenerator = 2
+
+// dhKeyPair is a client-side Diffie-Hellman key pair used to negotiate an
+// encrypted transport session.
+type dhKeyPair struct {
+	private *big.Int
+	public  *big.Int
+}
+
+// generateDHKeyPair picks a random private exponent and computes the
+// matching public key A = g^x mod p.
+func generateDHKeyPair() (*dhKeyPair, error) {
+	private, err := rand.Int(rand.Reader, dhPrime)
+	if err != nil {
+		return nil, err
+	}
+
+	public := new(big.Int).Exp(big.NewInt(dhGenerator), private, dhPrime)
+
+	return &dhKeyPair{private: private, public: public}, nil
+}
+
+// sharedSecret computes S = peerPublic^x mod p and left-pads it to the
+// byte length of the prime, as required before it is fed into HKDF.
+func (k *dhKeyPair) sharedSecret(peerPublic *big.Int) []byte {
+	s := new(big.Int).Exp(peerPublic, k.private, dhPrime)
+
+	padded := make([]byte, (dhPrime.BitLen()+7)/8)
+	s.FillBytes(padded)
+
+	return padded
+}
+
+// hkdfSHA256AESKey derives a 128-bit AES key from the DH shared secret
+// using HKDF-SHA256 (RFC 5869) with a zero salt and empty info, as
+// specified for dh-ietf1024-sha256-aes128-cbc-pkcs7.
+func hkdfSHA256AESKey(sharedSecret []byte) []byte {
+	salt := make([]byte, sha256.Size)
+
+	extractor := hmac.New(sha256.New, salt)
+	extractor.Write(sharedSecret)
+	prk := extractor.Sum(nil)
+
+	expander := hmac.New(sha256.New, prk)
+	expander.Write([]byte{0x01})
+	okm := expander.Sum(nil)
+
+	return okm[:16]
+}
+
+// pkcs7Pad pads data to a multiple of blockSize per PKCS#7.
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	padLen := blockSize - len(data)%blockSize
+	padding := make([]byte, padLen)
+	for i := range padding {
+		padding[i] = byte(padLen)
+	}
+	return append(data, padding...)
+}
+
+// pkcs7Unpad strips PKCS#7 padding from data, rejecting anything that isn't
+// a well-formed padding (wrong length, or trailing bytes that don't all
+// match padLen) rather than silently truncating to the wrong plaintext.
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("gkeyring: cannot unpad empty data")
+	}
+
+	padLen := int(data[len(data)-1])
+	if padLen == 0 || padLen > len(data) || padLen > aes.BlockSize {
+		return nil, fmt.Errorf("gkeyring: invalid PKCS#7 padding")
+	}
+
+	for _, b := range data[len(data)-padLen:] {
+		if int(b) != padLen {
+			return nil, fmt.Errorf("gkeyring: invalid PKCS#7 padding")
+		}
+	}
+
+	return data[:len(data)-padLen], nil
+}
+
+// aesCBCEncrypt generates a random IV, PKCS#7-pads plaintext and encrypts
+// it with AES-128-CBC under key. It returns the IV and the ciphertext.
+func aesCBCEncrypt(key, plaintext []byte) (iv, ciphertext []byte, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iv = make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, err
+	}
+
+	padded := pkcs7Pad(plaintext, aes.BlockSize)
+	ciphertext = make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+	return iv, ciphertext, nil
+}
+
+// aesCBCDecrypt decrypts ciphertext with AES-128-CBC under key and iv and
+// strips PKCS#7 padding.
+func aesCBCDecrypt(key, iv, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("gkeyring: ciphertext is not a multiple of the block size")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	return pkcs7Unpad(plaintext)
+}
@@ -0,0 +1,147 @@
+package gkeyring
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestDHPrime(t *testing.T) {
+	if bits := dhPrime.BitLen(); bits != 1024 {
+		t.Errorf("dhPrime.BitLen() = %d, want 1024", bits)
+	}
+}
+
+func TestDHSharedSecretAgreement(t *testing.T) {
+	alice, err := generateDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob, err := generateDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aliceSecret := alice.sharedSecret(bob.public)
+	bobSecret := bob.sharedSecret(alice.public)
+
+	if len(aliceSecret) != 128 {
+		t.Errorf("len(sharedSecret) = %d, want 128", len(aliceSecret))
+	}
+
+	if !bytes.Equal(aliceSecret, bobSecret) {
+		t.Error("alice and bob derived different shared secrets")
+	}
+}
+
+func TestDHSharedSecretPeerZero(t *testing.T) {
+	keyPair, err := generateDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := keyPair.sharedSecret(big.NewInt(0))
+	if len(secret) != 128 {
+		t.Errorf("len(sharedSecret) = %d, want 128", len(secret))
+	}
+}
+
+func TestHKDFSHA256AESKeyDeterministic(t *testing.T) {
+	shared := []byte("some shared secret bytes")
+
+	key1 := hkdfSHA256AESKey(shared)
+	key2 := hkdfSHA256AESKey(shared)
+
+	if len(key1) != 16 {
+		t.Errorf("len(key) = %d, want 16", len(key1))
+	}
+
+	if !bytes.Equal(key1, key2) {
+		t.Error("hkdfSHA256AESKey is not deterministic for the same input")
+	}
+
+	other := hkdfSHA256AESKey([]byte("different shared secret"))
+	if bytes.Equal(key1, other) {
+		t.Error("hkdfSHA256AESKey produced the same key for different inputs")
+	}
+}
+
+func TestAESCBCRoundTrip(t *testing.T) {
+	key := hkdfSHA256AESKey([]byte("shared secret"))
+	plaintext := []byte("hunter2")
+
+	iv, ciphertext, err := aesCBCEncrypt(key, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := aesCBCDecrypt(key, iv, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("aesCBCDecrypt() = %q, want %q", got, plaintext)
+	}
+}
+
+func TestAESCBCDecryptWrongKeyFails(t *testing.T) {
+	key := hkdfSHA256AESKey([]byte("shared secret"))
+	wrongKey := hkdfSHA256AESKey([]byte("a different shared secret"))
+
+	iv, ciphertext, err := aesCBCEncrypt(key, []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := aesCBCDecrypt(wrongKey, iv, ciphertext); err == nil {
+		t.Error("aesCBCDecrypt with the wrong key did not return an error")
+	}
+}
+
+func TestPKCS7PadUnpad(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte(""),
+		[]byte("a"),
+		[]byte("exactly16bytes!!"),
+		[]byte("a bit longer than one block"),
+	} {
+		padded := pkcs7Pad(data, 16)
+		if len(padded)%16 != 0 {
+			t.Errorf("pkcs7Pad(%q) has length %d, not a multiple of 16", data, len(padded))
+		}
+
+		unpadded, err := pkcs7Unpad(padded)
+		if err != nil {
+			t.Fatalf("pkcs7Unpad(pkcs7Pad(%q)): %v", data, err)
+		}
+
+		if !bytes.Equal(unpadded, data) {
+			t.Errorf("pkcs7Unpad(pkcs7Pad(%q)) = %q", data, unpadded)
+		}
+	}
+}
+
+func TestPKCS7UnpadRejectsCorruptPadding(t *testing.T) {
+	data := pkcs7Pad([]byte("hunter2"), 16)
+	data[len(data)-1] = 0xff // corrupt the last byte: too large to be a valid padLen
+
+	if _, err := pkcs7Unpad(data); err == nil {
+		t.Error("pkcs7Unpad accepted corrupt padding")
+	}
+
+	// Corrupting a padding byte other than the last, while leaving a
+	// plausible padLen, must also be rejected.
+	data = pkcs7Pad([]byte(""), 16)
+	data[0] = 0x00
+	if _, err := pkcs7Unpad(data); err == nil {
+		t.Error("pkcs7Unpad accepted padding with a mismatched interior byte")
+	}
+}
+
+func TestPKCS7UnpadRejectsEmpty(t *testing.T) {
+	if _, err := pkcs7Unpad(nil); err == nil {
+		t.Error("pkcs7Unpad accepted empty data")
+	}
+}
@@ -0,0 +1,117 @@
+package gkeyring
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus"
+)
+
+var (
+	// ErrNotFound is the expected error if the secret isn't found in the
+	// keyring.
+	ErrNotFound = fmt.Errorf("secret not found in keyring")
+
+	// ErrPromptDismissed is returned when a pending prompt is dismissed,
+	// either by the user or because its context was cancelled.
+	ErrPromptDismissed = fmt.Errorf("prompt was dismissed")
+
+	// ErrLocked is returned when an operation targets a collection or
+	// item that is locked and was never unlocked.
+	ErrLocked = &DbusError{Name: "org.freedesktop.Secret.Error.IsLocked"}
+
+	// ErrNoSession is returned when an operation is attempted on a
+	// session that was already closed or never opened.
+	ErrNoSession = &DbusError{Name: "org.freedesktop.Secret.Error.NoSession"}
+
+	// ErrNoSuchObject is returned when a collection, item or prompt path
+	// no longer exists on the bus.
+	ErrNoSuchObject = &DbusError{Name: "org.freedesktop.Secret.Error.NoSuchObject"}
+)
+
+// DbusError wraps a dbus error together with the Secret Service error name
+// it carries (e.g. "org.freedesktop.Secret.Error.IsLocked"), so callers
+// can branch on it with errors.Is/errors.As instead of string matching.
+type DbusError struct {
+	Name string
+	Err  error
+}
+
+func (e *DbusError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Name, e.Err)
+	}
+
+	return e.Name
+}
+
+func (e *DbusError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a *DbusError with the same Name, which is
+// what makes errors.Is(err, ErrLocked) work regardless of the underlying
+// dbus error's message.
+func (e *DbusError) Is(target error) bool {
+	t, ok := target.(*DbusError)
+	if !ok {
+		return false
+	}
+
+	return e.Name == t.Name
+}
+
+// wrapDbusError converts a raw dbus.Error into a *DbusError carrying its
+// error name. Errors that aren't a dbus.Error (e.g. connection failures)
+// are returned unchanged.
+func wrapDbusError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if dbusErr, ok := err.(dbus.Error); ok {
+		return &DbusError{Name: dbusErr.Name, Err: err}
+	}
+
+	return err
+}
+
+// MultiError aggregates errors from a multi-step operation where a
+// failure partway through must not hide errors from cleanup steps, such
+// as closing a session or dismissing a prompt, that ran afterward.
+type MultiError struct {
+	Errs []error
+}
+
+func (m *MultiError) Error() string {
+	parts := make([]string, len(m.Errs))
+	for i, err := range m.Errs {
+		parts[i] = err.Error()
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func (m *MultiError) Unwrap() []error {
+	return m.Errs
+}
+
+// joinErrors collects the non-nil errors in errs into a single error: nil
+// if none, the error itself if exactly one, or a *MultiError otherwise.
+func joinErrors(errs ...error) error {
+	var collected []error
+	for _, err := range errs {
+		if err != nil {
+			collected = append(collected, err)
+		}
+	}
+
+	switch len(collected) {
+	case 0:
+		return nil
+	case 1:
+		return collected[0]
+	default:
+		return &MultiError{Errs: collected}
+	}
+}
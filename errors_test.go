@@ -0,0 +1,73 @@
+package gkeyring
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestJoinErrorsNone(t *testing.T) {
+	if err := joinErrors(nil, nil); err != nil {
+		t.Errorf("joinErrors(nil, nil) = %v, want nil", err)
+	}
+}
+
+func TestJoinErrorsSingle(t *testing.T) {
+	want := fmt.Errorf("boom")
+
+	got := joinErrors(nil, want, nil)
+	if got != want {
+		t.Errorf("joinErrors() = %v, want the single non-nil error unwrapped", got)
+	}
+}
+
+func TestJoinErrorsMultiple(t *testing.T) {
+	first := fmt.Errorf("first")
+	second := fmt.Errorf("second")
+
+	got := joinErrors(first, second)
+
+	multi, ok := got.(*MultiError)
+	if !ok {
+		t.Fatalf("joinErrors() = %T, want *MultiError", got)
+	}
+
+	if len(multi.Errs) != 2 || multi.Errs[0] != first || multi.Errs[1] != second {
+		t.Errorf("multi.Errs = %v, want [%v %v]", multi.Errs, first, second)
+	}
+}
+
+func TestMultiErrorUnwrap(t *testing.T) {
+	first := fmt.Errorf("first")
+	second := &DbusError{Name: "org.freedesktop.Secret.Error.IsLocked"}
+
+	err := joinErrors(first, second)
+
+	if !errors.Is(err, second) {
+		t.Error("errors.Is did not find second error via MultiError.Unwrap")
+	}
+
+	if !errors.Is(err, ErrLocked) {
+		t.Error("errors.Is(err, ErrLocked) = false, want true via MultiError.Unwrap")
+	}
+}
+
+func TestDbusErrorIs(t *testing.T) {
+	err := &DbusError{Name: "org.freedesktop.Secret.Error.IsLocked", Err: fmt.Errorf("denied")}
+
+	if !errors.Is(err, ErrLocked) {
+		t.Error("errors.Is(err, ErrLocked) = false, want true for matching Name")
+	}
+
+	if errors.Is(err, ErrNoSession) {
+		t.Error("errors.Is(err, ErrNoSession) = true, want false for differing Name")
+	}
+}
+
+func TestDbusErrorIsWrapped(t *testing.T) {
+	wrapped := fmt.Errorf("get property %s.%s on %s: %w", itemInterface, "Type", "/", ErrLocked)
+
+	if !errors.Is(wrapped, ErrLocked) {
+		t.Error("errors.Is did not unwrap to the underlying *DbusError")
+	}
+}
@@ -1,372 +1,314 @@
+// Package gkeyring provides access to the freedesktop.org Secret Service
+// D-Bus API (as implemented by gnome-keyring, KWallet and KeePassXC).
+//
+// Set, Get, Delete and List are thin wrappers around the default collection
+// (whatever the "default" alias resolves to, or "login" if unset) for
+// callers who only need a single keyring. The Service,
+// Session, Collection and Item types expose the full API for callers who
+// need multiple collections, custom attributes or lifecycle control.
 package gkeyring
 
 import (
+	"context"
 	"fmt"
-
-	"github.com/godbus/dbus"
-)
-
-const (
-	serviceName         = "org.freedesktop.secrets"
-	servicePath         = "/org/freedesktop/secrets"
-	serviceInterface    = "org.freedesktop.Secret.Service"
-	collectionInterface = "org.freedesktop.Secret.Collection"
-	itemInterface       = "org.freedesktop.Secret.Item"
-	sessionInterface    = "org.freedesktop.Secret.Session"
-	promptInterface     = "org.freedesktop.Secret.Prompt"
-
-	collectionBasePath = "/org/freedesktop/secrets/collection/"
+	"time"
 )
 
-type secret struct {
-	Session     dbus.ObjectPath
-	Parameters  []byte
-	Value       []byte
-	ContentType string `dbus:"content_type"`
+// ItemInfo describes a single item, as returned by List and Search.
+type ItemInfo struct {
+	Label      string
+	Attributes map[string]string
+	Created    time.Time
+	Modified   time.Time
+	Locked     bool
 }
 
-func newSecret(session dbus.ObjectPath, sec string) secret {
-	return secret{
-		Session:     session,
-		Parameters:  []byte{},
-		Value:       []byte(sec),
-		ContentType: "text/plain; charset=utf8",
+// itemInfo fetches the metadata for item and assembles an ItemInfo.
+func itemInfo(item *Item) (ItemInfo, error) {
+	label, err := item.Label()
+	if err != nil {
+		return ItemInfo{}, err
 	}
-}
-
-// secretService is an interface for the Secret Service dbus API.
-type secretService struct {
-	*dbus.Conn
-	object dbus.BusObject
-}
 
+	attributes, err := item.Attributes()
+	if err != nil {
+		return ItemInfo{}, err
+	}
 
-var (
-	// ErrNotFound is the expected error if the secret isn't found in the
-	// keyring.
-	ErrNotFound = fmt.Errorf("secret not found in keyring")
-)
+	created, err := item.Created()
+	if err != nil {
+		return ItemInfo{}, err
+	}
 
+	modified, err := item.Modified()
+	if err != nil {
+		return ItemInfo{}, err
+	}
 
-// NewSecretService initializes a new secretService object.
-func newSecretService() (*secretService, error) {
-	conn, err := dbus.SessionBus()
+	locked, err := item.Locked()
 	if err != nil {
-		return nil, err
+		return ItemInfo{}, err
 	}
 
-	return &secretService{
-		conn,
-		conn.Object(serviceName, servicePath),
+	return ItemInfo{
+		Label:      label,
+		Attributes: attributes,
+		Created:    created,
+		Modified:   modified,
+		Locked:     locked,
 	}, nil
 }
 
+// itemInfos fetches the metadata for every item in items.
+func itemInfos(items []*Item) ([]ItemInfo, error) {
+	infos := make([]ItemInfo, len(items))
+	for i, item := range items {
+		info, err := itemInfo(item)
+		if err != nil {
+			return nil, err
+		}
+		infos[i] = info
+	}
 
+	return infos, nil
+}
 
-// GetCollection returns a collection from a name.
-func (s *secretService) getCollection(name string) dbus.BusObject {
-	return s.Object(serviceName, dbus.ObjectPath(collectionBasePath+name))
+// Set stores user and pass in the keyring under the defined service name,
+// in the default collection (whatever the "default" alias resolves to, or
+// "login" if unset).
+func Set(service, user, pass string) error {
+	return SetContext(context.Background(), service, user, pass)
 }
 
-// Unlock unlocks a collection.
-func (s *secretService) unlock(collection dbus.ObjectPath) error {
-	var unlocked []dbus.ObjectPath
-	var prompt dbus.ObjectPath
-	err := s.object.Call(serviceInterface+".Unlock", 0, []dbus.ObjectPath{collection}).Store(&unlocked, &prompt)
+// SetContext is like Set, but aborts a pending unlock prompt once ctx is
+// done. This is useful when the keyring is locked and no user is present
+// to type the passphrase.
+func SetContext(ctx context.Context, service, user, pass string) error {
+	s, err := Open()
 	if err != nil {
 		return err
 	}
+	defer s.Close()
 
-	_, v, err := s.handlePrompt(prompt)
+	collection, err := s.defaultCollectionObj()
 	if err != nil {
 		return err
 	}
 
-	collections := v.Value()
-	switch c := collections.(type) {
-	case []dbus.ObjectPath:
-		unlocked = append(unlocked, c...)
-	}
-
-	if len(unlocked) != 1 || unlocked[0] != collection {
-		return fmt.Errorf("failed to unlock correct collection '%v'", collection)
-	}
-
-	return nil
+	return setIn(ctx, s, collection, service, user, pass)
 }
 
-
-// CreateCollection with the supplied label.
-func (s *secretService) createCollection(label string) (dbus.BusObject, error) {
-	properties := map[string]dbus.Variant{
-		collectionInterface + ".Label": dbus.MakeVariant(label),
-	}
-	var collection, prompt dbus.ObjectPath
-	err := s.object.Call(serviceInterface+".CreateCollection", 0, properties, "").
-		Store(&collection, &prompt)
+// SetIn is like Set, but stores the secret in the named collection.
+// collection may be a collection label, an alias (such as "default"), or a
+// dbus object path.
+func SetIn(collection, service, user, pass string) error {
+	s, err := Open()
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer s.Close()
 
-	_, v, err := s.handlePrompt(prompt)
+	c, err := s.resolveCollection(collection)
 	if err != nil {
-		return nil, err
-	}
-
-	if v.String() != "" {
-		collection = dbus.ObjectPath(v.String())
+		return err
 	}
 
-	return s.Object(serviceName, collection), nil
+	return setIn(context.Background(), s, c, service, user, pass)
 }
 
-// CreateItem creates an item in a collection, with label, attributes and a
-// related secret.
-func (s *secretService) createItem(collection dbus.BusObject, label string, attributes map[string]string, secret secret) error {
-	properties := map[string]dbus.Variant{
-		itemInterface + ".Label":      dbus.MakeVariant(label),
-		itemInterface + ".Attributes": dbus.MakeVariant(attributes),
-	}
-
-	var item, prompt dbus.ObjectPath
-	err := collection.Call(collectionInterface+".CreateItem", 0,
-		properties, secret, true).Store(&item, &prompt)
+func setIn(ctx context.Context, s *Service, collection *Collection, service, user, pass string) (err error) {
+	session, err := s.OpenSession(AlgorithmPlain)
 	if err != nil {
 		return err
 	}
+	defer func() { err = joinErrors(err, session.Close()) }()
 
-	_, _, err = s.handlePrompt(prompt)
+	err = s.UnlockContext(ctx, collection.path)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-// handlePrompt checks if a prompt should be handles and handles it by
-// triggering the prompt and waiting for the Sercret service daemon to display
-// the prompt to the user.
-func (s *secretService) handlePrompt(prompt dbus.ObjectPath) (bool, dbus.Variant, error) {
-	if prompt != dbus.ObjectPath("/") {
-		err := s.Object(serviceName, prompt).Call(promptInterface+".Prompt", 0, "").Err
-		if err != nil {
-			return false, dbus.MakeVariant(""), err
-		}
-
-		promptSignal := make(chan *dbus.Signal, 1)
-		s.Signal(promptSignal)
-
-		signal := <-promptSignal
-		switch signal.Name {
-		case promptInterface + ".Completed":
-			dismissed := signal.Body[0].(bool)
-			result := signal.Body[1].(dbus.Variant)
-			return dismissed, result, nil
-		}
-
+	attributes := map[string]string{
+		"username": user,
+		"service":  service,
 	}
 
-	return false, dbus.MakeVariant(""), nil
+	_, err = collection.CreateItemContext(ctx,
+		fmt.Sprintf("Password for '%s' on '%s'", user, service),
+		attributes, session, pass)
+	return err
 }
 
+// Get gets a secret from the keyring given a service name and a user.
+func Get(service, user string) (string, error) {
+	return GetContext(context.Background(), service, user)
+}
 
-// SearchItems returns a list of items matching the search object.
-func (s *secretService) searchItems(collection dbus.BusObject, search interface{}) ([]dbus.ObjectPath, error) {
-	var results []dbus.ObjectPath
-	err := collection.Call(collectionInterface+".SearchItems", 0, search).Store(&results)
+// GetContext is like Get, but aborts a pending unlock prompt once ctx is
+// done.
+func GetContext(ctx context.Context, service, user string) (string, error) {
+	s, err := Open()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	defer s.Close()
 
-	return results, nil
-}
-
-// GetSecret gets secret from an item in a given session.
-func (s *secretService) getSecret(itemPath dbus.ObjectPath, session dbus.ObjectPath) (*secret, error) {
-	var secret secret
-	err := s.Object(serviceName, itemPath).Call(itemInterface+".GetSecret", 0, session).Store(&secret)
+	collection, err := s.defaultCollectionObj()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return &secret, nil
+	return getIn(ctx, s, collection, service, user)
 }
 
-
-
-func (s *secretService) deleteItem(itemPath dbus.ObjectPath) error {
-	var prompt dbus.ObjectPath
-	err := s.Object(serviceName, itemPath).Call(itemInterface+".Delete", 0).Store(&prompt)
-	if err != nil {
-		return err
-	}
-
-	_, _, err = s.handlePrompt(prompt)
+// GetIn is like Get, but looks the secret up in the named collection.
+func GetIn(collection, service, user string) (string, error) {
+	s, err := Open()
 	if err != nil {
-		return err
+		return "", err
 	}
+	defer s.Close()
 
-	return nil
-}
-
-
-func (s *secretService) openSession() (dbus.BusObject, error) {
-	var disregard dbus.Variant
-	var sessionPath dbus.ObjectPath
-	err := s.object.Call(serviceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&disregard, &sessionPath)
+	c, err := s.resolveCollection(collection)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return s.Object(serviceName, sessionPath), nil
-}
-
-func (s *secretService) closeSession(session dbus.BusObject) error {
-	return session.Call(sessionInterface+".Close", 0).Err
+	return getIn(context.Background(), s, c, service, user)
 }
 
-
-
-// findItem look up an item by service and user.
-func (s *secretService) findItem(service, user string) (dbus.ObjectPath, error) {
-	collection := s.getCollection("login")
-
-	search := map[string]string{
-		"username": user,
-		"service":  service,
-	}
-
-	err := s.unlock(collection.Path())
+func getIn(ctx context.Context, s *Service, collection *Collection, service, user string) (value string, err error) {
+	item, err := s.findItem(ctx, collection, service, user)
 	if err != nil {
 		return "", err
 	}
 
-	results, err := s.searchItems(collection, search)
+	session, err := s.OpenSession(AlgorithmPlain)
 	if err != nil {
 		return "", err
 	}
+	defer func() { err = joinErrors(err, session.Close()) }()
 
-	if len(results) == 0 {
-		return "", ErrNotFound
-	}
-
-	return results[0], nil
+	value, err = item.GetSecret(session)
+	return value, err
 }
 
-// ---------------------------------------------------------------------------------------------------------------------
-// PUBLIC API
-
-
-// Set stores user and pass in the keyring under the defined service name.
-func Set(service, user, pass string) error {
-	attributes := map[string]string{
-		"username": user,
-		"service":  service,
-	}
+// Delete deletes a secret, identified by service & user, from the keyring.
+func Delete(service, user string) error {
+	return DeleteContext(context.Background(), service, user)
+}
 
-	s, err := newSecretService()
+// DeleteContext is like Delete, but aborts a pending unlock prompt once ctx
+// is done.
+func DeleteContext(ctx context.Context, service, user string) error {
+	s, err := Open()
 	if err != nil {
 		return err
 	}
+	defer s.Close()
 
-	// open a session
-	session, err := s.openSession()
+	collection, err := s.defaultCollectionObj()
 	if err != nil {
 		return err
 	}
-	defer s.closeSession(session)
 
-	secret := newSecret(session.Path(), pass)
-
-	collection := s.getCollection("login")
+	return deleteIn(ctx, s, collection, service, user)
+}
 
-	err = s.unlock(collection.Path())
+// DeleteIn is like Delete, but removes the secret from the named
+// collection.
+func DeleteIn(collection, service, user string) error {
+	s, err := Open()
 	if err != nil {
 		return err
 	}
+	defer s.Close()
 
-	err = s.createItem(collection,
-		fmt.Sprintf("Password for '%s' on '%s'", user, service),
-		attributes, secret)
+	c, err := s.resolveCollection(collection)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return deleteIn(context.Background(), s, c, service, user)
 }
 
-// Get gets a secret from the keyring given a service name and a user.
-func Get(service, user string) (string, error) {
-	s, err := newSecretService()
+func deleteIn(ctx context.Context, s *Service, collection *Collection, service, user string) error {
+	item, err := s.findItem(ctx, collection, service, user)
 	if err != nil {
-		return "", err
+		return err
 	}
 
+	return item.DeleteContext(ctx)
+}
 
-	item, err := s.findItem(service, user)
-	if err != nil {
-		return "", err
-	}
+// List returns metadata for every item in the default collection (whatever
+// the "default" alias resolves to, or "login" if unset).
+func List() ([]ItemInfo, error) {
+	return ListContext(context.Background())
+}
 
-	// open a session
-	session, err := s.openSession()
+// ListContext is like List, but aborts a pending unlock prompt once ctx is
+// done.
+func ListContext(ctx context.Context) ([]ItemInfo, error) {
+	s, err := Open()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer s.closeSession(session)
+	defer s.Close()
 
-	secret, err := s.getSecret(item, session.Path())
+	collection, err := s.defaultCollectionObj()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(secret.Value), nil
+	return listIn(ctx, s, collection)
 }
 
-// Delete deletes a secret, identified by service & user, from the keyring.
-func Delete(service, user string) error {
-	s, err := newSecretService()
+// ListIn is like List, but lists items in the named collection. collection
+// may be a collection label, an alias (such as "default"), or a dbus
+// object path.
+func ListIn(collection string) ([]ItemInfo, error) {
+	s, err := Open()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer s.Close()
 
-	item, err := s.findItem(service, user)
+	c, err := s.resolveCollection(collection)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return s.deleteItem(item)
+	return listIn(context.Background(), s, c)
 }
 
-// List all secret items
-func List() (map[string]string, error) {
-	s, err := newSecretService()
+func listIn(ctx context.Context, s *Service, collection *Collection) ([]ItemInfo, error) {
+	err := s.UnlockContext(ctx, collection.path)
 	if err != nil {
 		return nil, err
 	}
 
-
-	collection := s.getCollection("login")
-	err = s.unlock(collection.Path())
+	items, err := collection.Items()
 	if err != nil {
 		return nil, err
 	}
 
-	//s.listItems(collection)
-
-
-	//for _, item := range items {
-	//	secret, err := svc.GetSecret(item, session.Path())
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//	log.Println(secret)
-	//
-	//}
+	return itemInfos(items)
+}
 
+// Search returns metadata for every item across all collections whose
+// attributes match attrs.
+func Search(attrs map[string]string) ([]ItemInfo, error) {
+	s, err := Open()
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
 
-	return nil, nil
+	items, err := s.SearchItems(attrs)
+	if err != nil {
+		return nil, err
+	}
 
+	return itemInfos(items)
 }
-
-
@@ -0,0 +1,171 @@
+package gkeyring
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus"
+)
+
+// Item is a single secret stored in a Collection.
+type Item struct {
+	service *Service
+	path    dbus.ObjectPath
+}
+
+// Path returns the dbus object path backing this item.
+func (i *Item) Path() dbus.ObjectPath {
+	return i.path
+}
+
+// Label returns the item's human readable label.
+func (i *Item) Label() (string, error) {
+	v, err := i.service.getProperty(i.path, itemInterface, "Label")
+	if err != nil {
+		return "", err
+	}
+
+	s, ok := v.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected type for Label property: %T", v.Value())
+	}
+
+	return s, nil
+}
+
+// Attributes returns the item's lookup attributes.
+func (i *Item) Attributes() (map[string]string, error) {
+	v, err := i.service.getProperty(i.path, itemInterface, "Attributes")
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, ok := v.Value().(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type for Attributes property: %T", v.Value())
+	}
+
+	return attrs, nil
+}
+
+// Type returns the item's Secret Service item type, such as
+// "org.freedesktop.Secret.Generic". Backends that don't expose this
+// property (notably KeePassXC) fall back to that default instead of
+// erroring.
+func (i *Item) Type() (string, error) {
+	v, err := i.service.getPropertyCompat(i.path, itemInterface, "Type")
+	if err != nil {
+		return "", err
+	}
+
+	s, ok := v.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected type for Type property: %T", v.Value())
+	}
+
+	return s, nil
+}
+
+// Locked reports whether the item is currently locked.
+func (i *Item) Locked() (bool, error) {
+	v, err := i.service.getProperty(i.path, itemInterface, "Locked")
+	if err != nil {
+		return false, err
+	}
+
+	locked, ok := v.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected type for Locked property: %T", v.Value())
+	}
+
+	return locked, nil
+}
+
+// Created returns the time the item was created.
+func (i *Item) Created() (time.Time, error) {
+	v, err := i.service.getProperty(i.path, itemInterface, "Created")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	created, ok := v.Value().(uint64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected type for Created property: %T", v.Value())
+	}
+
+	return time.Unix(int64(created), 0), nil
+}
+
+// Modified returns the time the item was last modified.
+func (i *Item) Modified() (time.Time, error) {
+	v, err := i.service.getProperty(i.path, itemInterface, "Modified")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	modified, ok := v.Value().(uint64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected type for Modified property: %T", v.Value())
+	}
+
+	return time.Unix(int64(modified), 0), nil
+}
+
+// GetSecret retrieves the item's secret value over session.
+func (i *Item) GetSecret(session *Session) (string, error) {
+	object := i.service.conn.Object(serviceName, i.path)
+
+	var sec secret
+	err := object.Call(itemInterface+".GetSecret", 0, session.path).Store(&sec)
+	if err != nil {
+		return "", fmt.Errorf("get secret for %s: %w", i.path, wrapDbusError(err))
+	}
+
+	value, err := session.decodeSecret(sec)
+	if err != nil {
+		return "", fmt.Errorf("get secret for %s: %w", i.path, err)
+	}
+
+	return value, nil
+}
+
+// SetSecret replaces the item's secret value over session.
+func (i *Item) SetSecret(session *Session, value string) error {
+	sec, err := session.encodeSecret(value)
+	if err != nil {
+		return fmt.Errorf("set secret for %s: %w", i.path, err)
+	}
+
+	object := i.service.conn.Object(serviceName, i.path)
+
+	if err := object.Call(itemInterface+".SetSecret", 0, sec).Err; err != nil {
+		return fmt.Errorf("set secret for %s: %w", i.path, wrapDbusError(err))
+	}
+
+	return nil
+}
+
+// Delete removes the item.
+func (i *Item) Delete() error {
+	return i.DeleteContext(context.Background())
+}
+
+// DeleteContext is like Delete, but aborts a pending unlock prompt once ctx
+// is done.
+func (i *Item) DeleteContext(ctx context.Context) error {
+	object := i.service.conn.Object(serviceName, i.path)
+
+	var prompt dbus.ObjectPath
+	err := object.Call(itemInterface+".Delete", 0).Store(&prompt)
+	if err != nil {
+		return fmt.Errorf("delete item %s: %w", i.path, wrapDbusError(err))
+	}
+
+	_, _, err = i.service.handlePromptContext(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("delete item %s: %w", i.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package gkeyring
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus"
+)
+
+// Prompt represents a pending org.freedesktop.Secret.Prompt, returned by
+// operations that may require the user to unlock a collection.
+type Prompt struct {
+	service *Service
+	path    dbus.ObjectPath
+}
+
+// Prompt triggers the prompt and blocks until the Secret Service daemon
+// reports it as completed. The returned bool reports whether the user
+// dismissed the prompt instead of completing it.
+func (p *Prompt) Prompt() (bool, dbus.Variant, error) {
+	return p.PromptContext(context.Background())
+}
+
+// PromptContext is like Prompt, but dismisses the prompt and returns
+// ErrPromptDismissed once ctx is done.
+func (p *Prompt) PromptContext(ctx context.Context) (bool, dbus.Variant, error) {
+	return p.service.handlePromptContext(ctx, p.path)
+}
+
+// Dismiss cancels a pending prompt.
+func (p *Prompt) Dismiss() error {
+	err := p.service.conn.Object(serviceName, p.path).Call(promptInterface+".Dismiss", 0).Err
+	if err != nil {
+		return fmt.Errorf("dismiss prompt %s: %w", p.path, wrapDbusError(err))
+	}
+
+	return nil
+}
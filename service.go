@@ -0,0 +1,344 @@
+package gkeyring
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus"
+)
+
+const (
+	serviceName         = "org.freedesktop.secrets"
+	servicePath         = "/org/freedesktop/secrets"
+	serviceInterface    = "org.freedesktop.Secret.Service"
+	collectionInterface = "org.freedesktop.Secret.Collection"
+	itemInterface       = "org.freedesktop.Secret.Item"
+	sessionInterface    = "org.freedesktop.Secret.Session"
+	promptInterface     = "org.freedesktop.Secret.Prompt"
+	propertiesInterface = "org.freedesktop.DBus.Properties"
+
+	collectionBasePath = "/org/freedesktop/secrets/collection/"
+
+	defaultCollection = "login"
+)
+
+// Service is a connection to the org.freedesktop.Secret.Service D-Bus API.
+// It owns the underlying dbus.Conn, so callers must call Close once they
+// are done with it.
+type Service struct {
+	conn   *dbus.Conn
+	object dbus.BusObject
+
+	// Backend identifies the Secret Service implementation behind this
+	// connection, so callers can adapt to features it doesn't support.
+	Backend BackendKind
+}
+
+// Open connects to the session bus and returns a Service bound to the
+// org.freedesktop.secrets object. The caller is responsible for calling
+// Close on the returned Service.
+func Open() (*Service, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		conn:    conn,
+		object:  conn.Object(serviceName, servicePath),
+		Backend: detectBackend(conn),
+	}, nil
+}
+
+// Close tears down the underlying dbus connection.
+func (s *Service) Close() error {
+	return s.conn.Close()
+}
+
+// collection builds a Collection from the well-known base path and a name,
+// without checking that it actually exists.
+func (s *Service) collection(name string) *Collection {
+	return &Collection{
+		service: s,
+		path:    dbus.ObjectPath(collectionBasePath + name),
+	}
+}
+
+// getProperty fetches a single property from the object at path via the
+// standard org.freedesktop.DBus.Properties interface.
+func (s *Service) getProperty(path dbus.ObjectPath, iface, name string) (dbus.Variant, error) {
+	var v dbus.Variant
+	err := s.conn.Object(serviceName, path).Call(propertiesInterface+".Get", 0, iface, name).Store(&v)
+	if err != nil {
+		return v, fmt.Errorf("get property %s.%s on %s: %w", iface, name, path, wrapDbusError(err))
+	}
+
+	return v, nil
+}
+
+// Collections returns every collection currently exposed by the service.
+func (s *Service) Collections() ([]*Collection, error) {
+	v, err := s.getProperty(servicePath, serviceInterface, "Collections")
+	if err != nil {
+		return nil, err
+	}
+
+	paths, ok := v.Value().([]dbus.ObjectPath)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type for Collections property: %T", v.Value())
+	}
+
+	collections := make([]*Collection, len(paths))
+	for i, path := range paths {
+		collections[i] = &Collection{service: s, path: path}
+	}
+
+	return collections, nil
+}
+
+// CreateCollection creates a new collection with the given label, aliased
+// under alias (pass "" for no alias).
+func (s *Service) CreateCollection(label, alias string) (*Collection, error) {
+	return s.CreateCollectionContext(context.Background(), label, alias)
+}
+
+// CreateCollectionContext is like CreateCollection, but aborts a pending
+// unlock prompt once ctx is done.
+func (s *Service) CreateCollectionContext(ctx context.Context, label, alias string) (*Collection, error) {
+	properties := map[string]dbus.Variant{
+		collectionInterface + ".Label": dbus.MakeVariant(label),
+	}
+
+	var collection, prompt dbus.ObjectPath
+	err := s.object.Call(serviceInterface+".CreateCollection", 0, properties, alias).
+		Store(&collection, &prompt)
+	if err != nil {
+		return nil, fmt.Errorf("create collection %q: %w", label, wrapDbusError(err))
+	}
+
+	_, v, err := s.handlePromptContext(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("create collection %q: %w", label, err)
+	}
+
+	if v.String() != "" {
+		collection = dbus.ObjectPath(v.String())
+	}
+
+	return &Collection{service: s, path: collection}, nil
+}
+
+// ReadAlias resolves an alias, such as "default", to the collection it
+// currently points at. It returns the empty path ("/") if the alias is
+// unset.
+func (s *Service) ReadAlias(name string) (dbus.ObjectPath, error) {
+	var path dbus.ObjectPath
+	err := s.object.Call(serviceInterface+".ReadAlias", 0, name).Store(&path)
+	if err != nil {
+		return "", fmt.Errorf("read alias %q: %w", name, wrapDbusError(err))
+	}
+
+	return path, nil
+}
+
+// SetAlias points alias at collection. Passing an empty collection path
+// removes the alias.
+func (s *Service) SetAlias(alias string, collection dbus.ObjectPath) error {
+	err := s.object.Call(serviceInterface+".SetAlias", 0, alias, collection).Err
+	if err != nil {
+		return fmt.Errorf("set alias %q to %s: %w", alias, collection, wrapDbusError(err))
+	}
+
+	return nil
+}
+
+// SearchItems searches every collection for items matching attrs.
+func (s *Service) SearchItems(attrs map[string]string) ([]*Item, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.object.Call(serviceInterface+".SearchItems", 0, attrs).Store(&unlocked, &locked)
+	if err != nil {
+		return nil, fmt.Errorf("search items: %w", wrapDbusError(err))
+	}
+
+	paths := append(unlocked, locked...)
+	items := make([]*Item, len(paths))
+	for i, path := range paths {
+		items[i] = &Item{service: s, path: path}
+	}
+
+	return items, nil
+}
+
+// Lock locks the given collections.
+func (s *Service) Lock(collections []*Collection) error {
+	paths := make([]dbus.ObjectPath, len(collections))
+	for i, c := range collections {
+		paths[i] = c.path
+	}
+
+	var locked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.object.Call(serviceInterface+".Lock", 0, paths).Store(&locked, &prompt)
+	if err != nil {
+		return fmt.Errorf("lock collections: %w", wrapDbusError(err))
+	}
+
+	_, _, err = s.handlePrompt(prompt)
+	if err != nil {
+		return fmt.Errorf("lock collections: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock unlocks the collection at path, prompting the user if necessary.
+func (s *Service) Unlock(path dbus.ObjectPath) error {
+	return s.UnlockContext(context.Background(), path)
+}
+
+// UnlockContext is like Unlock, but aborts a pending unlock prompt once ctx
+// is done.
+func (s *Service) UnlockContext(ctx context.Context, path dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.object.Call(serviceInterface+".Unlock", 0, []dbus.ObjectPath{path}).Store(&unlocked, &prompt)
+	if err != nil {
+		return fmt.Errorf("unlock %s: %w", path, wrapDbusError(err))
+	}
+
+	_, v, err := s.handlePromptContext(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("unlock %s: %w", path, err)
+	}
+
+	switch c := v.Value().(type) {
+	case []dbus.ObjectPath:
+		unlocked = append(unlocked, c...)
+	}
+
+	if len(unlocked) != 1 || unlocked[0] != path {
+		return fmt.Errorf("unlock %s: failed to unlock correct collection", path)
+	}
+
+	return nil
+}
+
+// handlePrompt checks if a prompt should be handled and handles it by
+// triggering the prompt and waiting for the Secret Service daemon to
+// display it to the user.
+func (s *Service) handlePrompt(prompt dbus.ObjectPath) (bool, dbus.Variant, error) {
+	return s.handlePromptContext(context.Background(), prompt)
+}
+
+// handlePromptContext is like handlePrompt, but triggers the prompt with a
+// match rule scoped to its own object path (instead of receiving every
+// signal on the bus) and dismisses it if ctx is done before the daemon
+// reports it as completed.
+func (s *Service) handlePromptContext(ctx context.Context, prompt dbus.ObjectPath) (bool, dbus.Variant, error) {
+	if prompt == dbus.ObjectPath("/") {
+		return false, dbus.MakeVariant(""), nil
+	}
+
+	promptObject := s.conn.Object(serviceName, prompt)
+
+	matchRule := fmt.Sprintf("type='signal',interface='%s',path='%s'", promptInterface, prompt)
+	if err := s.conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, matchRule).Err; err != nil {
+		return false, dbus.MakeVariant(""), fmt.Errorf("watch prompt %s: %w", prompt, wrapDbusError(err))
+	}
+	defer s.conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, matchRule)
+
+	promptSignal := make(chan *dbus.Signal, 1)
+	s.conn.Signal(promptSignal)
+	defer s.conn.RemoveSignal(promptSignal)
+
+	if err := promptObject.Call(promptInterface+".Prompt", 0, "").Err; err != nil {
+		return false, dbus.MakeVariant(""), fmt.Errorf("trigger prompt %s: %w", prompt, wrapDbusError(err))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			dismissErr := promptObject.Call(promptInterface+".Dismiss", 0).Err
+			return false, dbus.MakeVariant(""), joinErrors(ctx.Err(), ErrPromptDismissed, wrapDbusError(dismissErr))
+		case signal := <-promptSignal:
+			if signal.Path != prompt || signal.Name != promptInterface+".Completed" {
+				continue
+			}
+
+			dismissed := signal.Body[0].(bool)
+			result := signal.Body[1].(dbus.Variant)
+			if dismissed {
+				return true, result, ErrPromptDismissed
+			}
+
+			return false, result, nil
+		}
+	}
+}
+
+// resolveCollection resolves id - a collection label, an alias (such as
+// "default"), or a dbus object path - to a Collection. It falls back to
+// treating id as a literal collection name under collectionBasePath, which
+// keeps resolveCollection("login") working even when no alias was ever set.
+func (s *Service) resolveCollection(id string) (*Collection, error) {
+	if strings.HasPrefix(id, "/") {
+		return &Collection{service: s, path: dbus.ObjectPath(id)}, nil
+	}
+
+	if path, err := s.ReadAlias(id); err == nil && path != "" && path != dbus.ObjectPath("/") {
+		return &Collection{service: s, path: path}, nil
+	}
+
+	collections, err := s.Collections()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range collections {
+		if label, err := c.Label(); err == nil && label == id {
+			return c, nil
+		}
+	}
+
+	return s.collection(id), nil
+}
+
+// defaultCollectionObj resolves the "default" alias to a Collection,
+// falling back to the literal "login" collection if no alias is set.
+func (s *Service) defaultCollectionObj() (*Collection, error) {
+	path, err := s.ReadAlias("default")
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" || path == dbus.ObjectPath("/") {
+		return s.collection(defaultCollection), nil
+	}
+
+	return &Collection{service: s, path: path}, nil
+}
+
+// findItem looks up an item in collection by service and user attributes.
+func (s *Service) findItem(ctx context.Context, collection *Collection, service, user string) (*Item, error) {
+	search := map[string]string{
+		"username": user,
+		"service":  service,
+	}
+
+	err := s.UnlockContext(ctx, collection.path)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := collection.SearchItems(search)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return items[0], nil
+}
@@ -0,0 +1,147 @@
+package gkeyring
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/godbus/dbus"
+)
+
+// SessionAlgorithm selects the transport encryption negotiated with the
+// Secret Service daemon when opening a Session.
+type SessionAlgorithm string
+
+const (
+	// AlgorithmPlain sends secrets across the session bus in the clear.
+	// It is the default for backward compatibility.
+	AlgorithmPlain SessionAlgorithm = "plain"
+
+	// AlgorithmEncrypted negotiates a dh-ietf1024-sha256-aes128-cbc-pkcs7
+	// session: a Diffie-Hellman exchange over the RFC 2409 1024-bit MODP
+	// group, followed by HKDF-SHA256 key derivation and AES-128-CBC with
+	// PKCS#7 padding for every secret value.
+	AlgorithmEncrypted SessionAlgorithm = "dh-ietf1024-sha256-aes128-cbc-pkcs7"
+)
+
+// secret is the wire representation of org.freedesktop.Secret.Item's
+// Secret struct, as passed to CreateItem and returned from GetSecret.
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string `dbus:"content_type"`
+}
+
+func newSecret(session dbus.ObjectPath, sec string) secret {
+	return secret{
+		Session:     session,
+		Parameters:  []byte{},
+		Value:       []byte(sec),
+		ContentType: "text/plain; charset=utf8",
+	}
+}
+
+// Session is a negotiated transport session with the Secret Service
+// daemon, used to exchange secret values.
+type Session struct {
+	service   *Service
+	path      dbus.ObjectPath
+	algorithm SessionAlgorithm
+	aesKey    []byte // nil unless algorithm == AlgorithmEncrypted
+}
+
+// OpenSession negotiates a new transport session with the service using
+// algorithm. Use AlgorithmPlain for the historical plain-text behavior, or
+// AlgorithmEncrypted to keep secret values off the session bus in the
+// clear. The returned Session must be closed once no longer needed.
+func (s *Service) OpenSession(algorithm SessionAlgorithm) (*Session, error) {
+	switch algorithm {
+	case AlgorithmEncrypted:
+		return s.openEncryptedSession()
+	default:
+		return s.openPlainSession()
+	}
+}
+
+func (s *Service) openPlainSession() (*Session, error) {
+	var disregard dbus.Variant
+	var sessionPath dbus.ObjectPath
+	err := s.object.Call(serviceInterface+".OpenSession", 0, string(AlgorithmPlain), dbus.MakeVariant("")).
+		Store(&disregard, &sessionPath)
+	if err != nil {
+		return nil, fmt.Errorf("open plain session: %w", wrapDbusError(err))
+	}
+
+	return &Session{service: s, path: sessionPath, algorithm: AlgorithmPlain}, nil
+}
+
+func (s *Service) openEncryptedSession() (*Session, error) {
+	keyPair, err := generateDHKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("open encrypted session: %w", err)
+	}
+
+	var serverPublic dbus.Variant
+	var sessionPath dbus.ObjectPath
+	err = s.object.Call(serviceInterface+".OpenSession", 0,
+		string(AlgorithmEncrypted), dbus.MakeVariant(keyPair.public.Bytes())).
+		Store(&serverPublic, &sessionPath)
+	if err != nil {
+		return nil, fmt.Errorf("open encrypted session: %w", wrapDbusError(err))
+	}
+
+	serverPublicBytes, ok := serverPublic.Value().([]byte)
+	if !ok {
+		return nil, fmt.Errorf("open encrypted session: unexpected type for server public key: %T", serverPublic.Value())
+	}
+
+	shared := keyPair.sharedSecret(new(big.Int).SetBytes(serverPublicBytes))
+	aesKey := hkdfSHA256AESKey(shared)
+
+	return &Session{service: s, path: sessionPath, algorithm: AlgorithmEncrypted, aesKey: aesKey}, nil
+}
+
+// Close ends the session.
+func (session *Session) Close() error {
+	err := session.service.conn.Object(serviceName, session.path).Call(sessionInterface+".Close", 0).Err
+	if err != nil {
+		return fmt.Errorf("close session %s: %w", session.path, wrapDbusError(err))
+	}
+
+	return nil
+}
+
+// encodeSecret wraps value for transport over this session, encrypting it
+// if the session was negotiated with AlgorithmEncrypted.
+func (session *Session) encodeSecret(value string) (secret, error) {
+	if session.algorithm != AlgorithmEncrypted {
+		return newSecret(session.path, value), nil
+	}
+
+	iv, ciphertext, err := aesCBCEncrypt(session.aesKey, []byte(value))
+	if err != nil {
+		return secret{}, fmt.Errorf("encrypt secret: %w", err)
+	}
+
+	return secret{
+		Session:     session.path,
+		Parameters:  iv,
+		Value:       ciphertext,
+		ContentType: "text/plain; charset=utf8",
+	}, nil
+}
+
+// decodeSecret extracts the plain-text value carried by sec, decrypting it
+// if the session was negotiated with AlgorithmEncrypted.
+func (session *Session) decodeSecret(sec secret) (string, error) {
+	if session.algorithm != AlgorithmEncrypted {
+		return string(sec.Value), nil
+	}
+
+	plaintext, err := aesCBCDecrypt(session.aesKey, sec.Parameters, sec.Value)
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}